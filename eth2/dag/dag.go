@@ -0,0 +1,23 @@
+package dag
+
+/// DagNode is a single beacon block as seen by fork choice: just enough
+/// shape (parent/children links and a slot) for every algorithm in
+/// eth2/fork_choice to walk the tree, vote-count, and skip-ahead without
+/// needing anything else from the block body.
+type DagNode struct {
+	Key  [32]byte
+	Slot uint64
+
+	Parent   *DagNode
+	Children []*DagNode
+}
+
+/// BeaconDag is the shared view of the block tree that every ForkChoice
+/// implementation is bound to via SetDag. Start is the current justified
+/// root fork choice searches from by default; Nodes indexes every known
+/// block by root so AddAttestation/FindHead can resolve a [32]byte into a
+/// *DagNode.
+type BeaconDag struct {
+	Start *DagNode
+	Nodes map[[32]byte]*DagNode
+}
@@ -0,0 +1,36 @@
+package fork_choice
+
+import "lmd-ghost/eth2/dag"
+
+// AttestationTracker turns a validator's single current vote into the
+// +1/-1 ScoreChange pair ApplyScoreChanges expects, by remembering each
+// validator's previous target. Every implementation in this repo that
+// scores by attestation embeds one of these instead of reimplementing the
+// same bookkeeping.
+type AttestationTracker struct {
+	latest map[uint64]*dag.DagNode
+}
+
+// NewAttestationTracker returns a ready-to-use AttestationTracker.
+func NewAttestationTracker() AttestationTracker {
+	return AttestationTracker{latest: make(map[uint64]*dag.DagNode)}
+}
+
+// Vote records validatorIndex's vote for target, returning the ScoreChange
+// batch that moves their weight off their previous target (if any) and
+// onto the new one.
+func (t *AttestationTracker) Vote(validatorIndex uint64, target *dag.DagNode) []ScoreChange {
+	changes := make([]ScoreChange, 0, 2)
+	if previous, ok := t.latest[validatorIndex]; ok {
+		changes = append(changes, ScoreChange{Target: previous, ScoreDelta: -1})
+	}
+	changes = append(changes, ScoreChange{Target: target, ScoreDelta: 1})
+	t.latest[validatorIndex] = target
+	return changes
+}
+
+// Reset forgets every recorded vote, e.g. when OnStartChange invalidates
+// anything keyed off the old start.
+func (t *AttestationTracker) Reset() {
+	t.latest = make(map[uint64]*dag.DagNode)
+}
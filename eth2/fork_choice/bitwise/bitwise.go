@@ -0,0 +1,253 @@
+package bitwise
+
+import (
+	"bytes"
+	"fmt"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
+)
+
+/// BitwiseLMDGhost is the bitwise LMD-GHOST variant from Lighthouse's
+/// bitwise_lmd_ghost.rs: rather than re-scanning every child at every depth
+/// on the way down to the head, it keeps a running vote tally per
+/// (depth, prefix) pair, so a two-way choice point can usually be resolved
+/// by comparing two tallies instead of a child scan. The prefix is the
+/// block's child-index path from dag.Start (see pathPrefix), not bits of the
+/// block's hash.
+type BitwiseLMDGhost struct {
+	dag *dag.BeaconDag
+
+	// votes[depth][prefix] is the cumulative weight of every target whose
+	// ancestor at `depth` (counted from dag.Start) has tree-path `prefix`.
+	votes map[uint8]map[string]int64
+
+	// pathPrefixes caches each block's tree-path prefix (see pathPrefix),
+	// so repeated addDelta walks over the same ancestors don't redo the
+	// sibling-index lookup every time.
+	pathPrefixes map[*dag.DagNode]string
+
+	// latestTarget is the net weight currently on each target block. Only
+	// the bestChildByScan fallback reads it; addDelta updates votes from the
+	// delta alone.
+	latestTarget map[*dag.DagNode]int64
+
+	attestations fork_choice.AttestationTracker
+}
+
+func NewBitwiseLMDGhost() fork_choice.ForkChoice {
+	return &BitwiseLMDGhost{
+		votes:        make(map[uint8]map[string]int64),
+		pathPrefixes: make(map[*dag.DagNode]string),
+		latestTarget: make(map[*dag.DagNode]int64),
+		attestations: fork_choice.NewAttestationTracker(),
+	}
+}
+
+/// InitForkChoice builds a BitwiseLMDGhost already bound to the given dag.
+func InitForkChoice(d *dag.BeaconDag) fork_choice.ForkChoice {
+	gh := NewBitwiseLMDGhost()
+	gh.SetDag(d)
+	return gh
+}
+
+func (gh *BitwiseLMDGhost) SetDag(d *dag.BeaconDag) {
+	gh.dag = d
+}
+
+func init() {
+	fork_choice.RegisterAlgorithm(fork_choice.AlgorithmBitwise, InitForkChoice)
+}
+
+func (gh *BitwiseLMDGhost) OnNewNode(node *dag.DagNode) {
+	// free, at cost of head-function, same as the spec/cached implementations
+}
+
+func (gh *BitwiseLMDGhost) OnStartChange(newStart *dag.DagNode) {
+	// votes are keyed by depth from dag.Start, so a new start invalidates
+	// every entry - cheapest correct thing to do is to start over.
+	gh.votes = make(map[uint8]map[string]int64)
+	gh.pathPrefixes = make(map[*dag.DagNode]string)
+	gh.latestTarget = make(map[*dag.DagNode]int64)
+	gh.attestations.Reset()
+}
+
+/// ApplyScoreChanges walks from each target back up to dag.Start, adding the
+/// delta to the (depth, prefix) vote at every depth along the path.
+func (gh *BitwiseLMDGhost) ApplyScoreChanges(changes []fork_choice.ScoreChange) {
+	for _, change := range changes {
+		gh.addDelta(change.Target, change.ScoreDelta)
+		gh.latestTarget[change.Target] += change.ScoreDelta
+		if gh.latestTarget[change.Target] == 0 {
+			delete(gh.latestTarget, change.Target)
+		}
+	}
+}
+
+func (gh *BitwiseLMDGhost) addDelta(target *dag.DagNode, delta int64) {
+	if delta == 0 || gh.dag == nil {
+		return
+	}
+	depth := depthOf(gh.dag.Start, target)
+	for block, d := target, depth; d > 0; block, d = block.Parent, d-1 {
+		gh.voteMap(d)[gh.pathPrefix(block)] += delta
+	}
+}
+
+func (gh *BitwiseLMDGhost) voteMap(depth uint8) map[string]int64 {
+	m, ok := gh.votes[depth]
+	if !ok {
+		m = make(map[string]int64)
+		gh.votes[depth] = m
+	}
+	return m
+}
+
+/// HeadFn walks down from dag.Start one depth at a time. At each hop it
+/// tries to pick the heavier child by comparing the two (depth, prefix)
+/// vote totals directly; whenever the children don't split cleanly on a
+/// single bit (more than two children, or a tie) it falls back to a full
+/// child scan (bestChildByScan). That scan resolves ancestors with the plain
+/// recursive getAncestor walk, which is neither cached nor logarithmic.
+func (gh *BitwiseLMDGhost) HeadFn() *dag.DagNode {
+	return gh.headFrom(gh.dag.Start)
+}
+
+/// headFrom is the actual head search, parameterized on the starting block
+/// so FindHead can run it from an explicit justified root instead of always
+/// starting at gh.dag.Start. depth is computed relative to gh.dag.Start,
+/// since the vote table is keyed by depth from there, not from `start`.
+func (gh *BitwiseLMDGhost) headFrom(start *dag.DagNode) *dag.DagNode {
+	head := start
+	depth := depthOf(gh.dag.Start, start)
+
+	for {
+		if len(head.Children) == 0 {
+			return head
+		}
+
+		if len(head.Children) == 2 && depth < 255 {
+			left, right := head.Children[0], head.Children[1]
+			leftVotes := gh.voteMap(depth + 1)[gh.pathPrefix(left)]
+			rightVotes := gh.voteMap(depth + 1)[gh.pathPrefix(right)]
+			if leftVotes != rightVotes {
+				if leftVotes > rightVotes {
+					head = left
+				} else {
+					head = right
+				}
+				depth++
+				continue
+			}
+		}
+
+		head = gh.bestChildByScan(head)
+		depth++
+	}
+}
+
+/// bestChildByScan is the fallback for non-bit-aligned subtrees: it scans
+/// every child and tallies votes via getAncestor, same approach as
+/// SpecLMDGhost.getVoteCount. Ties are broken by the lexicographically
+/// smaller key, same as the other implementations in this repo.
+func (gh *BitwiseLMDGhost) bestChildByScan(head *dag.DagNode) *dag.DagNode {
+	var best *dag.DagNode
+	var bestScore int64
+	for _, child := range head.Children {
+		score := gh.getVoteCount(child)
+		if best == nil || score > bestScore ||
+			(score == bestScore && bytes.Compare(child.Key[:], best.Key[:]) < 0) {
+			bestScore = score
+			best = child
+		}
+	}
+	return best
+}
+
+func (gh *BitwiseLMDGhost) getVoteCount(block *dag.DagNode) int64 {
+	total := int64(0)
+	for target, weight := range gh.latestTarget {
+		if anc := gh.getAncestor(target, block.Slot); anc == block {
+			total += weight
+		}
+	}
+	return total
+}
+
+/// getAncestor is the plain recursive ancestor lookup, kept around purely
+/// as the fallback path used by bestChildByScan.
+func (gh *BitwiseLMDGhost) getAncestor(block *dag.DagNode, slot uint64) *dag.DagNode {
+	if block.Slot == slot {
+		return block
+	} else if block.Slot < slot {
+		return nil
+	}
+	return gh.getAncestor(block.Parent, slot)
+}
+
+func depthOf(start, block *dag.DagNode) uint8 {
+	depth := 0
+	for b := block; b != start && b != nil; b = b.Parent {
+		depth++
+	}
+	if depth > 255 {
+		return 255
+	}
+	return uint8(depth)
+}
+
+/// pathPrefix is the key into the per-depth vote table: it's built by
+/// walking from dag.Start down to block, appending one byte per level for
+/// the index of the child actually taken. Unlike keying off the block's
+/// own hash, two different blocks at the same depth can never collide on
+/// the same prefix, since a root-to-block path through the dag is unique -
+/// and unlike packing each level's index into a fixed-width int, appending
+/// to a string never drops earlier levels no matter how deep the tree
+/// gets, so that uniqueness guarantee actually holds at every depth, not
+/// just the first handful of levels.
+func (gh *BitwiseLMDGhost) pathPrefix(block *dag.DagNode) string {
+	if block == gh.dag.Start || block.Parent == nil {
+		return ""
+	}
+	if v, ok := gh.pathPrefixes[block]; ok {
+		return v
+	}
+
+	childIndex := byte(0)
+	for i, sibling := range block.Parent.Children {
+		if sibling == block {
+			childIndex = byte(i)
+			break
+		}
+	}
+
+	v := gh.pathPrefix(block.Parent) + string([]byte{childIndex})
+	gh.pathPrefixes[block] = v
+	return v
+}
+
+func (gh *BitwiseLMDGhost) AddBlock(block *dag.DagNode, _ *eth2spec.ChainSpec) error {
+	gh.OnNewNode(block)
+	return nil
+}
+
+/// AddAttestation replaces validatorIndex's previous vote (if any) with a
+/// vote for beaconBlockRoot, via the existing ApplyScoreChanges batch path.
+func (gh *BitwiseLMDGhost) AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, _ *eth2spec.ChainSpec) error {
+	target, ok := gh.dag.Nodes[beaconBlockRoot]
+	if !ok {
+		return fmt.Errorf("bitwise: unknown block root %x", beaconBlockRoot)
+	}
+
+	gh.ApplyScoreChanges(gh.attestations.Vote(validatorIndex, target))
+	return nil
+}
+
+func (gh *BitwiseLMDGhost) FindHead(justifiedRoot [32]byte, _ *eth2spec.ChainSpec) (*dag.DagNode, error) {
+	justified, ok := gh.dag.Nodes[justifiedRoot]
+	if !ok {
+		return nil, fmt.Errorf("bitwise: unknown justified root %x", justifiedRoot)
+	}
+	return gh.headFrom(justified), nil
+}
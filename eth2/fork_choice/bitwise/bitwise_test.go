@@ -0,0 +1,171 @@
+package bitwise
+
+import (
+	"fmt"
+	"testing"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	"lmd-ghost/eth2/fork_choice/choices/cached"
+	"lmd-ghost/eth2/fork_choice/choices/spec"
+	"lmd-ghost/eth2/fork_choice/testharness"
+)
+
+// chainImplementations are compared against BitwiseLMDGhost in these
+// tests/benchmarks, since the request asked for it to be checked against
+// the existing implementations in the repo's simulation harness.
+func chainImplementations() map[string]fork_choice.InitForkChoice {
+	return map[string]fork_choice.InitForkChoice{
+		"spec":    spec.InitForkChoice,
+		"cached":  cached.InitForkChoice,
+		"bitwise": InitForkChoice,
+	}
+}
+
+// TestReorg checks that a weight change flips the head to a heavier
+// sibling, agreeing with SpecLMDGhost and CachedLMDGhost.
+func TestReorg(t *testing.T) {
+	scenario := &testharness.Scenario{
+		Blocks: []testharness.BlockSpec{
+			{ID: "genesis", Slot: 0},
+			{ID: "b1", Parent: "genesis", Slot: 1},
+			{ID: "b2", Parent: "genesis", Slot: 1},
+		},
+		Weights:      []testharness.WeightSpec{{Validator: 0, Block: "b1"}},
+		ExpectedHead: "b1",
+		Steps: []testharness.Step{
+			{
+				Weights: []testharness.WeightSpec{
+					{Validator: 1, Block: "b2"},
+					{Validator: 2, Block: "b2"},
+				},
+				ExpectedHead: "b2",
+			},
+		},
+	}
+
+	for label, err := range testharness.RunAll(scenario, chainImplementations()) {
+		if err != nil {
+			t.Errorf("%s: %v", label, err)
+		}
+	}
+}
+
+// TestPathPrefixIsCollisionFree builds several unrelated nodes at the same
+// depth, under different parents, and checks that pathPrefix never assigns
+// any two of them the same value. This is the property the old hash-bit
+// prefix didn't have: two unrelated blocks could alias into the same
+// (depth, prefix) vote bucket purely by chance.
+func TestPathPrefixIsCollisionFree(t *testing.T) {
+	gh := NewBitwiseLMDGhost().(*BitwiseLMDGhost)
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh.SetDag(beaconDag)
+
+	genesis := testharness.NewTestNode("genesis", 0, nil)
+	beaconDag.Start = genesis
+
+	// Three separate subtrees hanging off genesis, each two levels deep,
+	// so there are several unrelated nodes at the same depth (2).
+	var depthTwoNodes []*dag.DagNode
+	for i := 0; i < 3; i++ {
+		branch := testharness.NewTestNode(testharness.BenchBlockID(i*2), 1, genesis)
+		leaf := testharness.NewTestNode(testharness.BenchBlockID(i*2+1), 2, branch)
+		depthTwoNodes = append(depthTwoNodes, leaf)
+	}
+
+	seen := make(map[string]*dag.DagNode, len(depthTwoNodes))
+	for _, node := range depthTwoNodes {
+		p := gh.pathPrefix(node)
+		if other, ok := seen[p]; ok {
+			t.Fatalf("pathPrefix collision between %x and %x", other.Key, node.Key)
+		}
+		seen[p] = node
+	}
+}
+
+// TestPathPrefixIsCollisionFreePastSixteenLevels builds two chains more
+// than 16 levels deep that take different children right at the start but
+// then follow the same child index (0) every level after that, so their
+// most recent 16 levels of choices are identical and only the divergence
+// at the very first hop tells them apart. A fixed-width encoding that
+// packs a handful of bits per level and shifts in new levels eventually
+// drops that first hop, aliasing the two chains together; pathPrefix must
+// not do that at any depth.
+func TestPathPrefixIsCollisionFreePastSixteenLevels(t *testing.T) {
+	gh := NewBitwiseLMDGhost().(*BitwiseLMDGhost)
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh.SetDag(beaconDag)
+
+	genesis := testharness.NewTestNode("genesis", 0, nil)
+	beaconDag.Start = genesis
+
+	const depth = 20
+	buildChain := func(label string) *dag.DagNode {
+		// Each chain forks from genesis as its own (only) child, so the
+		// two chains diverge at depth 1; every level after that always
+		// takes child index 0 of its single-child parent.
+		node := testharness.NewTestNode(label+"-fork", 1, genesis)
+		for d := 2; d <= depth; d++ {
+			node = testharness.NewTestNode(fmt.Sprintf("%s-%d", label, d), uint64(d), node)
+		}
+		return node
+	}
+
+	leafA := buildChain("a")
+	leafB := buildChain("b")
+
+	if p := gh.pathPrefix(leafA); p == gh.pathPrefix(leafB) {
+		t.Fatalf("pathPrefix collapsed two chains that diverged at depth 1 into the same prefix %q", p)
+	}
+}
+
+// BenchmarkHeadFn builds a chain that forks at every depth - one heavily
+// attested block continuing the canonical chain, and one lightly attested
+// dead-end sibling - and compares the cost of resolving the head against
+// spec and cached. This is the shape bitwise is actually built for: every
+// hop is a clean two-way split, so HeadFn can read a single majority
+// (depth, prefix) comparison instead of rescanning every validator's vote
+// at every depth like spec/cached have to.
+func BenchmarkHeadFn(b *testing.B) {
+	const chainLength = 256
+
+	for label, init := range chainImplementations() {
+		b.Run(label, func(b *testing.B) {
+			beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+			gh := init(beaconDag)
+
+			var parent *dag.DagNode
+			for i := 0; i < chainLength; i++ {
+				node := testharness.NewTestNode(testharness.BenchBlockID(i*2), uint64(i), parent)
+				deadEnd := testharness.NewTestNode(testharness.BenchBlockID(i*2+1), uint64(i), parent)
+				beaconDag.Nodes[node.Key] = node
+				beaconDag.Nodes[deadEnd.Key] = deadEnd
+				if i == 0 {
+					beaconDag.Start = node
+				}
+
+				if err := gh.AddBlock(node, nil); err != nil {
+					b.Fatalf("AddBlock: %v", err)
+				}
+				if err := gh.AddBlock(deadEnd, nil); err != nil {
+					b.Fatalf("AddBlock: %v", err)
+				}
+				if err := gh.AddAttestation(uint64(3*i), node.Key, nil); err != nil {
+					b.Fatalf("AddAttestation: %v", err)
+				}
+				if err := gh.AddAttestation(uint64(3*i+1), node.Key, nil); err != nil {
+					b.Fatalf("AddAttestation: %v", err)
+				}
+				if err := gh.AddAttestation(uint64(3*i+2), deadEnd.Key, nil); err != nil {
+					b.Fatalf("AddAttestation: %v", err)
+				}
+				parent = node
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				gh.HeadFn()
+			}
+		})
+	}
+}
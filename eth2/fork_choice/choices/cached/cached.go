@@ -1,8 +1,12 @@
 package cached
 
 import (
+	"bytes"
+	"fmt"
+
 	"lmd-ghost/eth2/dag"
 	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
 )
 
 type CacheKey [32 + 4]uint8
@@ -29,18 +33,33 @@ type CachedLMDGhost struct {
 	ancestors map[uint8]map[*dag.DagNode]*dag.DagNode
 
 	maxKnownSlot uint64
+
+	attestations fork_choice.AttestationTracker
 }
 
 func NewCachedLMDGhost() fork_choice.ForkChoice {
 	res := new(CachedLMDGhost)
+	res.LatestScores = make(map[*dag.DagNode]int64)
 	res.cache = make(map[CacheKey]*dag.DagNode)
 	res.ancestors = make(map[uint8]map[*dag.DagNode]*dag.DagNode)
 	for i := uint8(0); i < 16; i++ {
 		res.ancestors[i] = make(map[*dag.DagNode]*dag.DagNode)
 	}
+	res.attestations = fork_choice.NewAttestationTracker()
 	return res
 }
 
+/// InitForkChoice builds a CachedLMDGhost already bound to the given dag.
+func InitForkChoice(d *dag.BeaconDag) fork_choice.ForkChoice {
+	gh := NewCachedLMDGhost()
+	gh.SetDag(d)
+	return gh
+}
+
+func init() {
+	fork_choice.RegisterAlgorithm(fork_choice.AlgorithmCached, InitForkChoice)
+}
+
 /// The spec get_ancestor, but with caching, and skipping ahead logarithmically
 func (gh *CachedLMDGhost) getAncestor(block *dag.DagNode, slot uint64) *dag.DagNode {
 
@@ -128,25 +147,34 @@ func (gh *CachedLMDGhost) OnStartChange(newStart *dag.DagNode) {
 	// nothing to do when the start changes
 }
 
+func (gh *CachedLMDGhost) HeadFn() *dag.DagNode {
+	return gh.headFrom(gh.dag.Start)
+}
+
 /// Retrieves the head by *recursively* looking for the highest voted block
 //   at *every* block in the path from start to head.
-func (gh *CachedLMDGhost) HeadFn() *dag.DagNode {
+// headFrom takes the starting block as a parameter so FindHead can run the
+// same search from an explicit justified root instead of always starting
+// at gh.dag.Start. Vote ties are broken by the lexicographically smaller
+// key, same as the other implementations in this repo.
+func (gh *CachedLMDGhost) headFrom(start *dag.DagNode) *dag.DagNode {
 	// Minor difference:
 	// Normally you would have to filter for the active validators, and get their targets.
 	// We can just iterate over the values in the common-chain.
 	// This difference only really matters when there's many validators inactive,
 	//  and the client implementation doesn't store them separately.
 
-	head := gh.dag.Start
+	head := start
 	for {
 		if len(head.Children) == 0 {
 			return head
 		}
-		bestItem := head.Children[0]
-		var bestScore int64 = 0
+		var bestItem *dag.DagNode
+		var bestScore int64
 		for _, child := range head.Children {
 			childVotes := gh.getVoteCount(child)
-			if childVotes > bestScore {
+			if bestItem == nil || childVotes > bestScore ||
+				(childVotes == bestScore && bytes.Compare(child.Key[:], bestItem.Key[:]) < 0) {
 				bestScore = childVotes
 				bestItem = child
 			}
@@ -158,9 +186,35 @@ func (gh *CachedLMDGhost) HeadFn() *dag.DagNode {
 func (gh *CachedLMDGhost) getVoteCount(block *dag.DagNode) int64 {
 	totalWeight := int64(0)
 	for target, weight := range gh.LatestScores {
-		if anc := gh.getAncestor(target, block.Slot); anc != nil && anc == target {
+		if anc := gh.getAncestor(target, block.Slot); anc != nil && anc == block {
 			totalWeight += weight
 		}
 	}
 	return totalWeight
 }
+
+func (gh *CachedLMDGhost) AddBlock(block *dag.DagNode, _ *eth2spec.ChainSpec) error {
+	gh.OnNewNode(block)
+	gh.BlockIn(block)
+	return nil
+}
+
+/// AddAttestation replaces validatorIndex's previous vote (if any) with a
+/// vote for beaconBlockRoot, via the existing ApplyScoreChanges batch path.
+func (gh *CachedLMDGhost) AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, _ *eth2spec.ChainSpec) error {
+	target, ok := gh.dag.Nodes[beaconBlockRoot]
+	if !ok {
+		return fmt.Errorf("cached: unknown block root %x", beaconBlockRoot)
+	}
+
+	gh.ApplyScoreChanges(gh.attestations.Vote(validatorIndex, target))
+	return nil
+}
+
+func (gh *CachedLMDGhost) FindHead(justifiedRoot [32]byte, _ *eth2spec.ChainSpec) (*dag.DagNode, error) {
+	justified, ok := gh.dag.Nodes[justifiedRoot]
+	if !ok {
+		return nil, fmt.Errorf("cached: unknown justified root %x", justifiedRoot)
+	}
+	return gh.headFrom(justified), nil
+}
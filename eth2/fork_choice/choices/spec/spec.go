@@ -1,8 +1,12 @@
 package spec
 
 import (
+	"bytes"
+	"fmt"
+
 	"lmd-ghost/eth2/dag"
 	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
 )
 
 /// The naive, but readable, spec implementation
@@ -11,10 +15,26 @@ type SpecLMDGhost struct {
 	dag *dag.BeaconDag
 
 	LatestScores map[*dag.DagNode]int64
+
+	attestations fork_choice.AttestationTracker
 }
 
 func NewSpecLMDGhost() fork_choice.ForkChoice {
-	return new(SpecLMDGhost)
+	return &SpecLMDGhost{
+		LatestScores: make(map[*dag.DagNode]int64),
+		attestations: fork_choice.NewAttestationTracker(),
+	}
+}
+
+/// InitForkChoice builds a SpecLMDGhost already bound to the given dag.
+func InitForkChoice(d *dag.BeaconDag) fork_choice.ForkChoice {
+	gh := NewSpecLMDGhost()
+	gh.SetDag(d)
+	return gh
+}
+
+func init() {
+	fork_choice.RegisterAlgorithm(fork_choice.AlgorithmSpec, InitForkChoice)
 }
 
 func (gh *SpecLMDGhost) SetDag(dag *dag.BeaconDag) {
@@ -42,25 +62,34 @@ func (gh *SpecLMDGhost) OnStartChange(newStart *dag.DagNode) {
 	// nothing to do when the start changes
 }
 
+func (gh *SpecLMDGhost) HeadFn() *dag.DagNode {
+	return gh.headFrom(gh.dag.Start)
+}
+
 /// Retrieves the head by *recursively* looking for the highest voted block
 //   at *every* block in the path from start to head.
-func (gh *SpecLMDGhost) HeadFn() *dag.DagNode {
+// headFrom takes the starting block as a parameter so FindHead can run the
+// same search from an explicit justified root instead of always starting
+// at gh.dag.Start. Vote ties are broken by the lexicographically smaller
+// key, same as the other implementations in this repo.
+func (gh *SpecLMDGhost) headFrom(start *dag.DagNode) *dag.DagNode {
 	// Minor difference:
 	// Normally you would have to filter for the active validators, and get their targets.
 	// We can just iterate over the values in the common-chain.
 	// This difference only really matters when there's many validators inactive,
 	//  and the client implementation doesn't store them separately.
 
-	head := gh.dag.Start
+	head := start
 	for {
 		if len(head.Children) == 0 {
 			return head
 		}
-		bestItem := head.Children[0]
-		var bestScore int64 = 0
+		var bestItem *dag.DagNode
+		var bestScore int64
 		for _, child := range head.Children {
 			childVotes := gh.getVoteCount(child)
-			if childVotes > bestScore {
+			if bestItem == nil || childVotes > bestScore ||
+				(childVotes == bestScore && bytes.Compare(child.Key[:], bestItem.Key[:]) < 0) {
 				bestScore = childVotes
 				bestItem = child
 			}
@@ -72,7 +101,7 @@ func (gh *SpecLMDGhost) HeadFn() *dag.DagNode {
 func (gh *SpecLMDGhost) getVoteCount(block *dag.DagNode) int64 {
 	totalWeight := int64(0)
 	for target, weight := range gh.LatestScores {
-		if anc := gh.getAncestor(target, block.Slot); anc != nil && anc == target {
+		if anc := gh.getAncestor(target, block.Slot); anc != nil && anc == block {
 			totalWeight += weight
 		}
 	}
@@ -89,3 +118,28 @@ func (gh *SpecLMDGhost) getAncestor(block *dag.DagNode, slot uint64) *dag.DagNod
 		return gh.getAncestor(block.Parent, slot)
 	}
 }
+
+func (gh *SpecLMDGhost) AddBlock(block *dag.DagNode, _ *eth2spec.ChainSpec) error {
+	gh.OnNewNode(block)
+	return nil
+}
+
+/// AddAttestation replaces validatorIndex's previous vote (if any) with a
+/// vote for beaconBlockRoot, via the existing ApplyScoreChanges batch path.
+func (gh *SpecLMDGhost) AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, _ *eth2spec.ChainSpec) error {
+	target, ok := gh.dag.Nodes[beaconBlockRoot]
+	if !ok {
+		return fmt.Errorf("spec: unknown block root %x", beaconBlockRoot)
+	}
+
+	gh.ApplyScoreChanges(gh.attestations.Vote(validatorIndex, target))
+	return nil
+}
+
+func (gh *SpecLMDGhost) FindHead(justifiedRoot [32]byte, _ *eth2spec.ChainSpec) (*dag.DagNode, error) {
+	justified, ok := gh.dag.Nodes[justifiedRoot]
+	if !ok {
+		return nil, fmt.Errorf("spec: unknown justified root %x", justifiedRoot)
+	}
+	return gh.headFrom(justified), nil
+}
@@ -0,0 +1,38 @@
+package fork_choice
+
+import (
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/spec"
+)
+
+// ScoreChange is a single weight delta to apply to a block in the next
+// ApplyScoreChanges batch.
+type ScoreChange struct {
+	Target     *dag.DagNode
+	ScoreDelta int64
+}
+
+// ForkChoice is implemented by every fork choice algorithm in this repo
+// (spec, cached, and onwards). All of them are driven the same way: the dag
+// tells them about new blocks and score changes, and HeadFn answers the
+// only question that matters: what's the current head?
+//
+// AddBlock, AddAttestation and FindHead mirror the Lighthouse ForkChoice
+// trait shape: a single block/attestation at a time, and an explicit
+// justified root to search from. HeadFn/ApplyScoreChanges remain as a thin
+// batch-oriented compatibility path so existing callers (and benchmarks)
+// keep working unchanged.
+type ForkChoice interface {
+	SetDag(dag *dag.BeaconDag)
+	OnNewNode(node *dag.DagNode)
+	ApplyScoreChanges(changes []ScoreChange)
+	OnStartChange(newStart *dag.DagNode)
+	HeadFn() *dag.DagNode
+
+	AddBlock(block *dag.DagNode, spec *spec.ChainSpec) error
+	AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, spec *spec.ChainSpec) error
+	FindHead(justifiedRoot [32]byte, spec *spec.ChainSpec) (*dag.DagNode, error)
+}
+
+// InitForkChoice constructs a ForkChoice already bound to the given dag.
+type InitForkChoice func(dag *dag.BeaconDag) ForkChoice
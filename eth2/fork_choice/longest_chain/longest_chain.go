@@ -0,0 +1,112 @@
+package longest_chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
+)
+
+/// LongestChainLMDGhost is a slot-based longest-chain rule that ignores
+/// attestations entirely, matching the LongestChain variant in Lighthouse's
+/// fork_choice crate. It's a cheap baseline to compare the LMD-GHOST
+/// implementations against, and a drop-in choice for nodes that want
+/// pre-attestation behavior during bootstrap.
+type LongestChainLMDGhost struct {
+	dag *dag.BeaconDag
+
+	leaves map[*dag.DagNode]struct{}
+}
+
+func NewLongestChainLMDGhost() fork_choice.ForkChoice {
+	return &LongestChainLMDGhost{
+		leaves: make(map[*dag.DagNode]struct{}),
+	}
+}
+
+/// InitForkChoice builds a LongestChainLMDGhost already bound to the given dag.
+func InitForkChoice(d *dag.BeaconDag) fork_choice.ForkChoice {
+	gh := NewLongestChainLMDGhost()
+	gh.SetDag(d)
+	return gh
+}
+
+func (gh *LongestChainLMDGhost) SetDag(d *dag.BeaconDag) {
+	gh.dag = d
+}
+
+func init() {
+	fork_choice.RegisterAlgorithm(fork_choice.AlgorithmLongestChain, InitForkChoice)
+}
+
+/// OnNewNode tracks the current set of leaves: the new node is always a
+/// leaf, and its parent (if any) no longer is.
+func (gh *LongestChainLMDGhost) OnNewNode(node *dag.DagNode) {
+	gh.leaves[node] = struct{}{}
+	if node.Parent != nil {
+		delete(gh.leaves, node.Parent)
+	}
+}
+
+func (gh *LongestChainLMDGhost) ApplyScoreChanges(changes []fork_choice.ScoreChange) {
+	// attestations don't affect this rule
+}
+
+func (gh *LongestChainLMDGhost) OnStartChange(newStart *dag.DagNode) {
+	// nothing to do when the start changes
+}
+
+/// HeadFn returns the leaf with the highest slot, breaking ties by the
+/// lexicographically smallest key.
+func (gh *LongestChainLMDGhost) HeadFn() *dag.DagNode {
+	return gh.headFrom(gh.dag.Start)
+}
+
+/// headFrom finds the best leaf reachable from start, so FindHead can run
+/// the same search from an explicit justified root instead of always
+/// starting at gh.dag.Start.
+func (gh *LongestChainLMDGhost) headFrom(start *dag.DagNode) *dag.DagNode {
+	var head *dag.DagNode
+	for leaf := range gh.leaves {
+		if !isDescendant(start, leaf) {
+			continue
+		}
+		if head == nil || leaf.Slot > head.Slot ||
+			(leaf.Slot == head.Slot && bytes.Compare(leaf.Key[:], head.Key[:]) < 0) {
+			head = leaf
+		}
+	}
+	if head == nil {
+		return start
+	}
+	return head
+}
+
+func isDescendant(ancestor, block *dag.DagNode) bool {
+	for b := block; b != nil; b = b.Parent {
+		if b == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (gh *LongestChainLMDGhost) AddBlock(block *dag.DagNode, _ *eth2spec.ChainSpec) error {
+	gh.OnNewNode(block)
+	return nil
+}
+
+// AddAttestation is a no-op: this rule doesn't consider attestations.
+func (gh *LongestChainLMDGhost) AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, _ *eth2spec.ChainSpec) error {
+	return nil
+}
+
+func (gh *LongestChainLMDGhost) FindHead(justifiedRoot [32]byte, _ *eth2spec.ChainSpec) (*dag.DagNode, error) {
+	justified, ok := gh.dag.Nodes[justifiedRoot]
+	if !ok {
+		return nil, fmt.Errorf("longest_chain: unknown justified root %x", justifiedRoot)
+	}
+	return gh.headFrom(justified), nil
+}
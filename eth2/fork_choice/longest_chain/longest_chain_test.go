@@ -0,0 +1,88 @@
+package longest_chain
+
+import (
+	"bytes"
+	"testing"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice/testharness"
+)
+
+// TestReorg checks that extending one branch past its sibling's slot flips
+// the head to the now-longer branch, and that an exact-slot tie between
+// leaves is broken by the lexicographically smaller key - the same rule
+// every other implementation in this repo uses.
+func TestReorg(t *testing.T) {
+	gh := NewLongestChainLMDGhost().(*LongestChainLMDGhost)
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh.SetDag(beaconDag)
+
+	genesis := testharness.NewTestNode("genesis", 0, nil)
+	beaconDag.Start = genesis
+	a := testharness.NewTestNode("a", 1, genesis)
+	b := testharness.NewTestNode("b", 1, genesis)
+
+	for _, node := range []*dag.DagNode{genesis, a, b} {
+		beaconDag.Nodes[node.Key] = node
+		if err := gh.AddBlock(node, nil); err != nil {
+			t.Fatalf("AddBlock(%x): %v", node.Key, err)
+		}
+	}
+
+	wantTieWinner := a
+	if bytes.Compare(b.Key[:], a.Key[:]) < 0 {
+		wantTieWinner = b
+	}
+	head := gh.HeadFn()
+	if head == nil || head.Key != wantTieWinner.Key {
+		t.Fatalf("tied leaves: expected lexicographically smaller key %x, got %v", wantTieWinner.Key, head)
+	}
+
+	// Extending b past a's slot must flip the head to b, regardless of
+	// which one won the earlier tie.
+	bLeaf := testharness.NewTestNode("b-leaf", 2, b)
+	beaconDag.Nodes[bLeaf.Key] = bLeaf
+	if err := gh.AddBlock(bLeaf, nil); err != nil {
+		t.Fatalf("AddBlock(b-leaf): %v", err)
+	}
+	head = gh.HeadFn()
+	if head == nil || head.Key != bLeaf.Key {
+		t.Fatalf("expected head to flip to b-leaf after it outgrew its sibling, got %v", head)
+	}
+}
+
+// TestFindHeadFromJustifiedRoot checks that FindHead searches only the
+// justified root's subtree, even when a higher-slot leaf exists elsewhere
+// in the dag. HeadFn (from dag.Start) must still prefer that higher-slot
+// leaf.
+func TestFindHeadFromJustifiedRoot(t *testing.T) {
+	gh := NewLongestChainLMDGhost().(*LongestChainLMDGhost)
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh.SetDag(beaconDag)
+
+	genesis := testharness.NewTestNode("genesis", 0, nil)
+	beaconDag.Start = genesis
+	x := testharness.NewTestNode("x", 1, genesis)
+	xLeaf := testharness.NewTestNode("x-leaf", 5, x)
+	y := testharness.NewTestNode("y", 1, genesis)
+	yLeaf := testharness.NewTestNode("y-leaf", 2, y)
+
+	for _, node := range []*dag.DagNode{genesis, x, xLeaf, y, yLeaf} {
+		beaconDag.Nodes[node.Key] = node
+		if err := gh.AddBlock(node, nil); err != nil {
+			t.Fatalf("AddBlock(%x): %v", node.Key, err)
+		}
+	}
+
+	if head := gh.HeadFn(); head == nil || head.Key != xLeaf.Key {
+		t.Fatalf("HeadFn: expected x-leaf (highest slot), got %v", head)
+	}
+
+	head, err := gh.FindHead(y.Key, nil)
+	if err != nil {
+		t.Fatalf("FindHead(y): %v", err)
+	}
+	if head == nil || head.Key != yLeaf.Key {
+		t.Fatalf("FindHead(y): expected y-leaf, got %v", head)
+	}
+}
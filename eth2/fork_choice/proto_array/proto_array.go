@@ -0,0 +1,262 @@
+package proto_array
+
+import (
+	"bytes"
+	"fmt"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
+)
+
+// noNode marks the absence of a parent/best-child/best-descendant link.
+const noNode = -1
+
+// ProtoNode is one entry of the contiguous proto-array. Index within the
+// slice *is* the node's identity everywhere else in this package; Root is
+// only needed to go from a *dag.DagNode back to that index.
+type ProtoNode struct {
+	Root [32]byte
+
+	Parent int
+
+	// Delta is weight accumulated by ApplyScoreChanges since the last
+	// HeadFn call, not yet folded into Weight.
+	Delta  int64
+	Weight int64
+
+	BestChild      int
+	BestDescendant int
+}
+
+/// ProtoArrayLMDGhost is the proto_array fork choice, as used to replace the
+/// original LMD-GHOST implementations in Lighthouse (lighthouse-labs/lighthouse#804).
+/// Instead of walking the dag, it keeps a flat append-only slice of nodes and
+/// resolves the head by following best-descendant links in O(depth).
+type ProtoArrayLMDGhost struct {
+	dag *dag.BeaconDag
+
+	nodes   []ProtoNode
+	blocks  []*dag.DagNode
+	indices map[[32]byte]int
+
+	attestations fork_choice.AttestationTracker
+}
+
+func NewProtoArrayLMDGhost() fork_choice.ForkChoice {
+	return &ProtoArrayLMDGhost{
+		indices:      make(map[[32]byte]int),
+		attestations: fork_choice.NewAttestationTracker(),
+	}
+}
+
+/// InitForkChoice builds a ProtoArrayLMDGhost already bound to the given dag,
+/// for callers that don't need to hold on to the concrete type.
+func InitForkChoice(d *dag.BeaconDag) fork_choice.ForkChoice {
+	gh := NewProtoArrayLMDGhost()
+	gh.SetDag(d)
+	return gh
+}
+
+func (gh *ProtoArrayLMDGhost) SetDag(d *dag.BeaconDag) {
+	gh.dag = d
+}
+
+func init() {
+	fork_choice.RegisterAlgorithm(fork_choice.AlgorithmProtoArray, InitForkChoice)
+}
+
+/// OnNewNode appends a node to the array and hooks it into its parent's
+/// best-child/best-descendant chain. A freshly appended node has weight 0,
+/// and the parent's best child is re-evaluated right away: the new node
+/// takes over only if no sibling is heavier and no equally weighted sibling
+/// has a smaller root. Otherwise it can take over only once HeadFn has
+/// folded enough weight into it.
+func (gh *ProtoArrayLMDGhost) OnNewNode(node *dag.DagNode) {
+	parentIndex := noNode
+	if node.Parent != nil {
+		if pi, ok := gh.indices[node.Parent.Key]; ok {
+			parentIndex = pi
+		}
+	}
+
+	index := len(gh.nodes)
+	gh.nodes = append(gh.nodes, ProtoNode{
+		Root:           node.Key,
+		Parent:         parentIndex,
+		BestChild:      noNode,
+		BestDescendant: index,
+	})
+	gh.blocks = append(gh.blocks, node)
+	gh.indices[node.Key] = index
+
+	if parentIndex != noNode {
+		gh.maybeUpdateBestChildAndDescendant(parentIndex)
+	}
+}
+
+/// ApplyScoreChanges only buffers the deltas on the target nodes. The actual
+/// propagation happens in HeadFn, in a single bottom-up pass.
+func (gh *ProtoArrayLMDGhost) ApplyScoreChanges(changes []fork_choice.ScoreChange) {
+	for _, change := range changes {
+		if index, ok := gh.indices[change.Target.Key]; ok {
+			gh.nodes[index].Delta += change.ScoreDelta
+		}
+	}
+}
+
+/// HeadFn walks the array once, from tip to root, folding each node's
+/// buffered delta into its weight and pushing the same delta up to its
+/// parent. Because children always sit after their parents in the array,
+/// a single reverse pass is enough to keep every best-child/best-descendant
+/// link consistent - no recursion, no ancestor map.
+func (gh *ProtoArrayLMDGhost) HeadFn() *dag.DagNode {
+	for i := len(gh.nodes) - 1; i >= 0; i-- {
+		node := &gh.nodes[i]
+		delta := node.Delta
+		node.Weight += delta
+		node.Delta = 0
+
+		if node.Parent != noNode {
+			gh.nodes[node.Parent].Delta += delta
+			gh.maybeUpdateBestChildAndDescendant(node.Parent)
+		}
+	}
+
+	return gh.headFrom(gh.dag.Start)
+}
+
+/// headFrom resolves the head as seen from an arbitrary justified block,
+/// instead of always starting at gh.dag.Start.
+func (gh *ProtoArrayLMDGhost) headFrom(start *dag.DagNode) *dag.DagNode {
+	startIndex, ok := gh.indices[start.Key]
+	if !ok || gh.nodes[startIndex].BestDescendant == noNode {
+		return start
+	}
+	return gh.blocks[gh.nodes[startIndex].BestDescendant]
+}
+
+/// maybeUpdateBestChildAndDescendant re-evaluates parentIndex's best child
+/// from scratch over all of its children, breaking weight ties by the
+/// lexicographically smaller root, same as the other implementations here.
+/// It has to scan every child rather than just compare the changed one
+/// against the previously recorded best, since a weight *decrease* on the
+/// current best child can only be caught by re-checking it against its
+/// siblings.
+func (gh *ProtoArrayLMDGhost) maybeUpdateBestChildAndDescendant(parentIndex int) {
+	parent := &gh.nodes[parentIndex]
+	parentBlock := gh.blocks[parentIndex]
+
+	best := noNode
+	for _, childBlock := range parentBlock.Children {
+		childIndex, ok := gh.indices[childBlock.Key]
+		if !ok {
+			continue
+		}
+		if best == noNode {
+			best = childIndex
+			continue
+		}
+
+		candidate := &gh.nodes[childIndex]
+		current := &gh.nodes[best]
+		if candidate.Weight > current.Weight ||
+			(candidate.Weight == current.Weight && bytes.Compare(candidate.Root[:], current.Root[:]) < 0) {
+			best = childIndex
+		}
+	}
+
+	parent.BestChild = best
+	if best == noNode {
+		parent.BestDescendant = parentIndex
+	} else {
+		parent.BestDescendant = gh.nodes[best].BestDescendant
+	}
+}
+
+/// OnStartChange prunes every node that isn't a descendant of the new start
+/// out of the array and rewrites the remaining parent/best-child/best-
+/// descendant indices in place, so the array stays contiguous and index 0
+/// is always the start. Keeping by array index alone isn't enough: nodes
+/// are appended in insertion order, not tree order, so an off-branch
+/// sibling that forked off an ancestor of newStart can still sit at a
+/// higher index than newStart - it has to be walked out via its actual
+/// dag.DagNode.Children links instead.
+func (gh *ProtoArrayLMDGhost) OnStartChange(newStart *dag.DagNode) {
+	newStartIndex, ok := gh.indices[newStart.Key]
+	if !ok || newStartIndex == 0 {
+		return
+	}
+
+	keep := make(map[int]bool, len(gh.nodes)-newStartIndex)
+	var walk func(index int)
+	walk = func(index int) {
+		if keep[index] {
+			return
+		}
+		keep[index] = true
+		for _, childBlock := range gh.blocks[index].Children {
+			if childIndex, ok := gh.indices[childBlock.Key]; ok {
+				walk(childIndex)
+			}
+		}
+	}
+	walk(newStartIndex)
+
+	prunedNodes := make([]ProtoNode, 0, len(keep))
+	prunedBlocks := make([]*dag.DagNode, 0, len(keep))
+	oldToNew := make(map[int]int, len(keep))
+
+	for i := newStartIndex; i < len(gh.nodes); i++ {
+		if !keep[i] {
+			continue
+		}
+		oldToNew[i] = len(prunedNodes)
+		prunedNodes = append(prunedNodes, gh.nodes[i])
+		prunedBlocks = append(prunedBlocks, gh.blocks[i])
+	}
+
+	rebase := func(index int) int {
+		if newIndex, ok := oldToNew[index]; ok {
+			return newIndex
+		}
+		return noNode
+	}
+
+	prunedIndices := make(map[[32]byte]int, len(prunedNodes))
+	for i := range prunedNodes {
+		prunedNodes[i].Parent = rebase(prunedNodes[i].Parent)
+		prunedNodes[i].BestChild = rebase(prunedNodes[i].BestChild)
+		prunedNodes[i].BestDescendant = rebase(prunedNodes[i].BestDescendant)
+		prunedIndices[prunedNodes[i].Root] = i
+	}
+
+	gh.nodes = prunedNodes
+	gh.blocks = prunedBlocks
+	gh.indices = prunedIndices
+}
+
+func (gh *ProtoArrayLMDGhost) AddBlock(block *dag.DagNode, _ *eth2spec.ChainSpec) error {
+	gh.OnNewNode(block)
+	return nil
+}
+
+/// AddAttestation replaces validatorIndex's previous vote (if any) with a
+/// vote for beaconBlockRoot, via the existing ApplyScoreChanges batch path.
+func (gh *ProtoArrayLMDGhost) AddAttestation(validatorIndex uint64, beaconBlockRoot [32]byte, _ *eth2spec.ChainSpec) error {
+	target, ok := gh.dag.Nodes[beaconBlockRoot]
+	if !ok {
+		return fmt.Errorf("proto_array: unknown block root %x", beaconBlockRoot)
+	}
+
+	gh.ApplyScoreChanges(gh.attestations.Vote(validatorIndex, target))
+	return nil
+}
+
+func (gh *ProtoArrayLMDGhost) FindHead(justifiedRoot [32]byte, _ *eth2spec.ChainSpec) (*dag.DagNode, error) {
+	justified, ok := gh.dag.Nodes[justifiedRoot]
+	if !ok {
+		return nil, fmt.Errorf("proto_array: unknown justified root %x", justifiedRoot)
+	}
+	return gh.headFrom(justified), nil
+}
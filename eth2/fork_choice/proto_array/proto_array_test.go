@@ -0,0 +1,130 @@
+package proto_array
+
+import (
+	"testing"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	"lmd-ghost/eth2/fork_choice/choices/cached"
+	"lmd-ghost/eth2/fork_choice/choices/spec"
+	"lmd-ghost/eth2/fork_choice/testharness"
+)
+
+// chainImplementations are compared against ProtoArrayLMDGhost in these
+// tests/benchmarks, since those are the two reference implementations the
+// request asked this package to be checked against.
+func chainImplementations() map[string]fork_choice.InitForkChoice {
+	return map[string]fork_choice.InitForkChoice{
+		"spec":        spec.InitForkChoice,
+		"cached":      cached.InitForkChoice,
+		"proto_array": InitForkChoice,
+	}
+}
+
+// TestReorg checks that a weight change on an existing best child correctly
+// flips the head to a heavier sibling, agreeing with SpecLMDGhost and
+// CachedLMDGhost. This is the scenario maybeUpdateBestChildAndDescendant
+// used to get wrong: re-confirming the current best child without
+// comparing it against its siblings once its own weight changed.
+func TestReorg(t *testing.T) {
+	scenario := &testharness.Scenario{
+		Blocks: []testharness.BlockSpec{
+			{ID: "genesis", Slot: 0},
+			{ID: "b1", Parent: "genesis", Slot: 1},
+			{ID: "b2", Parent: "genesis", Slot: 1},
+		},
+		Weights:      []testharness.WeightSpec{{Validator: 0, Block: "b1"}},
+		ExpectedHead: "b1",
+		Steps: []testharness.Step{
+			{
+				Weights: []testharness.WeightSpec{
+					{Validator: 1, Block: "b2"},
+					{Validator: 2, Block: "b2"},
+				},
+				ExpectedHead: "b2",
+			},
+		},
+	}
+
+	for label, err := range testharness.RunAll(scenario, chainImplementations()) {
+		if err != nil {
+			t.Errorf("%s: %v", label, err)
+		}
+	}
+}
+
+// TestOnStartChangePrunesOffBranchSiblings builds a dag where a sibling
+// branch forks off an ancestor of the new start and was appended *after*
+// the new start, so it sits at a higher array index despite not being a
+// descendant. OnStartChange used to keep any node whose index was >= the
+// new start's, turning that sibling into a bogus second root; it must now
+// be dropped entirely.
+func TestOnStartChangePrunesOffBranchSiblings(t *testing.T) {
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh := NewProtoArrayLMDGhost().(*ProtoArrayLMDGhost)
+	gh.SetDag(beaconDag)
+
+	genesis := testharness.NewTestNode("genesis", 0, nil)
+	newStart := testharness.NewTestNode("start", 1, genesis)
+	keep := testharness.NewTestNode("keep", 2, newStart)
+	// offBranch forks off genesis (an ancestor of newStart), but is
+	// appended after newStart and keep, so its array index is higher.
+	offBranch := testharness.NewTestNode("off-branch", 1, genesis)
+
+	for _, node := range []*dag.DagNode{genesis, newStart, keep, offBranch} {
+		beaconDag.Nodes[node.Key] = node
+		if err := gh.AddBlock(node, nil); err != nil {
+			t.Fatalf("AddBlock(%x): %v", node.Key, err)
+		}
+	}
+
+	gh.OnStartChange(newStart)
+
+	if _, ok := gh.indices[offBranch.Key]; ok {
+		t.Fatalf("off-branch sibling survived pruning, should have been dropped")
+	}
+	if _, ok := gh.indices[newStart.Key]; !ok {
+		t.Fatalf("new start itself is missing after pruning")
+	}
+	if _, ok := gh.indices[keep.Key]; !ok {
+		t.Fatalf("descendant of the new start is missing after pruning")
+	}
+	if got := len(gh.nodes); got != 2 {
+		t.Fatalf("expected 2 nodes to remain after pruning, got %d", got)
+	}
+}
+
+// BenchmarkHeadFn compares how expensive a single HeadFn call is once a
+// reasonably deep, single-branch chain with one vote per block has been
+// built up, across proto_array and the two reference implementations.
+func BenchmarkHeadFn(b *testing.B) {
+	const chainLength = 256
+
+	for label, init := range chainImplementations() {
+		b.Run(label, func(b *testing.B) {
+			beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+			gh := init(beaconDag)
+
+			var parent *dag.DagNode
+			for i := 0; i < chainLength; i++ {
+				node := testharness.NewTestNode(testharness.BenchBlockID(i), uint64(i), parent)
+				beaconDag.Nodes[node.Key] = node
+				if i == 0 {
+					beaconDag.Start = node
+				}
+				if err := gh.AddBlock(node, nil); err != nil {
+					b.Fatalf("AddBlock: %v", err)
+				}
+				if err := gh.AddAttestation(uint64(i), node.Key, nil); err != nil {
+					b.Fatalf("AddAttestation: %v", err)
+				}
+				parent = node
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				gh.HeadFn()
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package fork_choice
+
+import (
+	"fmt"
+
+	"lmd-ghost/eth2/dag"
+)
+
+// ForkChoiceAlgorithm identifies one of the registered fork choice
+// implementations, mirroring the ForkChoiceAlgorithm enum in Lighthouse's
+// fork_choice/src/lib.rs.
+type ForkChoiceAlgorithm string
+
+const (
+	AlgorithmSpec         ForkChoiceAlgorithm = "spec"
+	AlgorithmCached       ForkChoiceAlgorithm = "cached"
+	AlgorithmBitwise      ForkChoiceAlgorithm = "bitwise"
+	AlgorithmProtoArray   ForkChoiceAlgorithm = "proto_array"
+	AlgorithmLongestChain ForkChoiceAlgorithm = "longest_chain"
+)
+
+// Registry maps each known ForkChoiceAlgorithm to its constructor. Every
+// implementation package registers itself here from an init(), so New and
+// List don't need to know about any of them directly.
+var Registry = make(map[ForkChoiceAlgorithm]InitForkChoice)
+
+// RegisterAlgorithm is called by each implementation package's init() to
+// add itself to the Registry.
+func RegisterAlgorithm(algorithm ForkChoiceAlgorithm, init InitForkChoice) {
+	Registry[algorithm] = init
+}
+
+// UnknownAlgorithmError is returned by New when asked for an algorithm that
+// hasn't been registered (usually because its package was never imported).
+type UnknownAlgorithmError struct {
+	Algorithm ForkChoiceAlgorithm
+}
+
+func (e *UnknownAlgorithmError) Error() string {
+	return fmt.Sprintf("fork_choice: unknown algorithm %q", string(e.Algorithm))
+}
+
+/// New constructs a ForkChoice for the given algorithm, bound to d. Callers
+/// that want to switch algorithms at runtime (e.g. behind a CLI flag) can do
+/// so through this single entry point instead of importing each package's
+/// own constructor, e.g. fc, err := fork_choice.New("cached", d).
+func New(algorithm ForkChoiceAlgorithm, d *dag.BeaconDag) (ForkChoice, error) {
+	init, ok := Registry[algorithm]
+	if !ok {
+		return nil, &UnknownAlgorithmError{Algorithm: algorithm}
+	}
+	return init(d), nil
+}
+
+// List returns every algorithm currently registered.
+func List() []ForkChoiceAlgorithm {
+	algorithms := make([]ForkChoiceAlgorithm, 0, len(Registry))
+	for algorithm := range Registry {
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms
+}
@@ -0,0 +1,65 @@
+package fork_choice_test
+
+import (
+	"testing"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	_ "lmd-ghost/eth2/fork_choice/bitwise"
+	_ "lmd-ghost/eth2/fork_choice/choices/cached"
+	_ "lmd-ghost/eth2/fork_choice/choices/spec"
+	_ "lmd-ghost/eth2/fork_choice/longest_chain"
+	_ "lmd-ghost/eth2/fork_choice/proto_array"
+)
+
+// TestAllAlgorithmsRegister checks that every algorithm package in this
+// repo actually self-registers via its init(), so fork_choice.New can
+// construct any of them from just its name, without the caller importing
+// the concrete package.
+func TestAllAlgorithmsRegister(t *testing.T) {
+	want := []fork_choice.ForkChoiceAlgorithm{
+		fork_choice.AlgorithmSpec,
+		fork_choice.AlgorithmCached,
+		fork_choice.AlgorithmBitwise,
+		fork_choice.AlgorithmProtoArray,
+		fork_choice.AlgorithmLongestChain,
+	}
+
+	got := make(map[fork_choice.ForkChoiceAlgorithm]bool)
+	for _, algorithm := range fork_choice.List() {
+		got[algorithm] = true
+	}
+
+	for _, algorithm := range want {
+		if !got[algorithm] {
+			t.Errorf("algorithm %q never registered itself", algorithm)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected exactly %d registered algorithms, got %d: %v", len(want), len(got), fork_choice.List())
+	}
+}
+
+func TestNewConstructsRegisteredAlgorithm(t *testing.T) {
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+
+	gh, err := fork_choice.New(fork_choice.AlgorithmSpec, beaconDag)
+	if err != nil {
+		t.Fatalf("New(AlgorithmSpec): %v", err)
+	}
+	if gh == nil {
+		t.Fatal("New(AlgorithmSpec) returned a nil ForkChoice")
+	}
+}
+
+func TestNewUnknownAlgorithm(t *testing.T) {
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+
+	_, err := fork_choice.New("not-a-real-algorithm", beaconDag)
+	if err == nil {
+		t.Fatal("expected an error for an unknown algorithm, got nil")
+	}
+	if _, ok := err.(*fork_choice.UnknownAlgorithmError); !ok {
+		t.Fatalf("expected *UnknownAlgorithmError, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,192 @@
+package testharness
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	eth2spec "lmd-ghost/eth2/spec"
+)
+
+// defaultSpec is used to drive AddBlock/AddAttestation: none of the
+// scenarios this harness runs need anything from it today, and every
+// implementation in this repo ignores the spec argument, but the
+// ForkChoice interface requires one.
+var defaultSpec = &eth2spec.ChainSpec{}
+
+// BlockSpec is one entry of a scenario's `blocks` list.
+type BlockSpec struct {
+	ID     string `yaml:"id"`
+	Parent string `yaml:"parent"`
+	Slot   uint64 `yaml:"slot"`
+}
+
+// WeightSpec is one entry of a scenario's `weights` (or `attestations`)
+// list: validator casts a vote for block.
+type WeightSpec struct {
+	Validator uint64 `yaml:"validator"`
+	Block     string `yaml:"block"`
+}
+
+// Step is one point in a scenario's timeline at which the head is checked,
+// after the blocks/weights up to (and including) it have been applied.
+type Step struct {
+	Blocks       []BlockSpec  `yaml:"blocks"`
+	Weights      []WeightSpec `yaml:"weights"`
+	Attestations []WeightSpec `yaml:"attestations"`
+	ExpectedHead string       `yaml:"expected_head"`
+}
+
+// Scenario mirrors the upstream Lighthouse fork choice YAML test format:
+// a genesis set of blocks/weights, optionally followed by further steps
+// that each apply more blocks/weights and check the head.
+type Scenario struct {
+	Blocks       []BlockSpec  `yaml:"blocks"`
+	Weights      []WeightSpec `yaml:"weights"`
+	Attestations []WeightSpec `yaml:"attestations"`
+	ExpectedHead string       `yaml:"expected_head"`
+	Steps        []Step       `yaml:"steps"`
+}
+
+/// LoadScenario reads and parses a single scenario YAML file. The schema is
+/// intentionally the same one Lighthouse's yaml-rust fork choice tests use,
+/// so those files can be fed in here verbatim.
+func LoadScenario(path string) (*Scenario, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("testharness: %v", err)
+	}
+
+	scenario := new(Scenario)
+	if err := yaml.Unmarshal(raw, scenario); err != nil {
+		return nil, fmt.Errorf("testharness: %v", err)
+	}
+	return scenario, nil
+}
+
+// IDToKey turns a human-readable block id from the YAML into a stable
+// [32]byte root, so scenarios can reference blocks by short names like
+// "b1" instead of spelling out hashes.
+func IDToKey(id string) [32]byte {
+	return sha256.Sum256([]byte(id))
+}
+
+// NewTestNode builds a *dag.DagNode keyed off id via IDToKey, wiring it
+// into parent.Children when parent is non-nil. Tests across this repo that
+// need to build a dag by hand instead of driving it through a Scenario
+// share this instead of each defining their own.
+func NewTestNode(id string, slot uint64, parent *dag.DagNode) *dag.DagNode {
+	node := &dag.DagNode{Key: IDToKey(id), Slot: slot, Parent: parent}
+	if parent != nil {
+		parent.Children = append(parent.Children, node)
+	}
+	return node
+}
+
+// BenchBlockID generates a short, distinct id for the i'th block of a
+// benchmark chain.
+func BenchBlockID(i int) string {
+	return fmt.Sprintf("bench-%d", i)
+}
+
+/// Run builds a fresh dag from the scenario, drives the given
+/// fork_choice.ForkChoice implementation through every block/weight in
+/// order, and checks the expected head at genesis and after each step.
+/// It returns the first mismatch found, or nil if the implementation
+/// agreed with every expected head in the scenario.
+func Run(scenario *Scenario, init fork_choice.InitForkChoice) error {
+	beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+	gh := init(beaconDag)
+
+	if err := applyBlocksAndWeights(beaconDag, gh, scenario.Blocks, scenario.Weights, scenario.Attestations); err != nil {
+		return err
+	}
+	if scenario.ExpectedHead != "" {
+		if err := checkHead(gh, scenario.ExpectedHead, IDToKey); err != nil {
+			return err
+		}
+	}
+
+	for i, step := range scenario.Steps {
+		if err := applyBlocksAndWeights(beaconDag, gh, step.Blocks, step.Weights, step.Attestations); err != nil {
+			return fmt.Errorf("testharness: step %d: %v", i, err)
+		}
+		if err := checkHead(gh, step.ExpectedHead, IDToKey); err != nil {
+			return fmt.Errorf("testharness: step %d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+/// RunAll runs the same scenario against every implementation in impls,
+/// keyed by a label (e.g. "spec", "cached"), and reports every mismatch
+/// rather than stopping at the first one - the whole point of this harness
+/// is to verify that the implementations agree with each other, not just
+/// that one of them is right.
+func RunAll(scenario *Scenario, impls map[string]fork_choice.InitForkChoice) map[string]error {
+	results := make(map[string]error, len(impls))
+	for label, init := range impls {
+		results[label] = Run(scenario, init)
+	}
+	return results
+}
+
+func applyBlocksAndWeights(beaconDag *dag.BeaconDag, gh fork_choice.ForkChoice, blocks []BlockSpec, weights []WeightSpec, attestations []WeightSpec) error {
+	for _, b := range blocks {
+		node := &dag.DagNode{
+			Key:  IDToKey(b.ID),
+			Slot: b.Slot,
+		}
+
+		if b.Parent != "" {
+			parent, ok := beaconDag.Nodes[IDToKey(b.Parent)]
+			if !ok {
+				return fmt.Errorf("testharness: block %q references unknown parent %q", b.ID, b.Parent)
+			}
+			node.Parent = parent
+			parent.Children = append(parent.Children, node)
+		} else if beaconDag.Start == nil {
+			beaconDag.Start = node
+		}
+
+		beaconDag.Nodes[node.Key] = node
+		if err := gh.AddBlock(node, defaultSpec); err != nil {
+			return fmt.Errorf("testharness: block %q: %v", b.ID, err)
+		}
+	}
+
+	for _, w := range append(append([]WeightSpec{}, weights...), attestations...) {
+		if _, ok := beaconDag.Nodes[IDToKey(w.Block)]; !ok {
+			return fmt.Errorf("testharness: vote from validator %d references unknown block %q", w.Validator, w.Block)
+		}
+		if err := gh.AddAttestation(w.Validator, IDToKey(w.Block), defaultSpec); err != nil {
+			return fmt.Errorf("testharness: vote from validator %d: %v", w.Validator, err)
+		}
+	}
+
+	return nil
+}
+
+func checkHead(gh fork_choice.ForkChoice, expectedID string, IDToKey func(string) [32]byte) error {
+	if expectedID == "" {
+		return nil
+	}
+
+	head := gh.HeadFn()
+	if head == nil || head.Key != IDToKey(expectedID) {
+		return fmt.Errorf("testharness: expected head %q, got %x", expectedID, headKey(head))
+	}
+	return nil
+}
+
+func headKey(head *dag.DagNode) [32]byte {
+	if head == nil {
+		return [32]byte{}
+	}
+	return head.Key
+}
@@ -0,0 +1,101 @@
+package testharness
+
+import (
+	"path/filepath"
+	"testing"
+
+	"lmd-ghost/eth2/dag"
+	"lmd-ghost/eth2/fork_choice"
+	"lmd-ghost/eth2/fork_choice/bitwise"
+	"lmd-ghost/eth2/fork_choice/choices/cached"
+	"lmd-ghost/eth2/fork_choice/choices/spec"
+	"lmd-ghost/eth2/fork_choice/proto_array"
+)
+
+// weightedImplementations are the algorithms that actually consider
+// attestations, i.e. every registered implementation except longest_chain.
+// The canonical scenarios under testdata/ exist specifically to check that
+// these implementations agree with each other.
+func weightedImplementations() map[string]fork_choice.InitForkChoice {
+	return map[string]fork_choice.InitForkChoice{
+		"spec":        spec.InitForkChoice,
+		"cached":      cached.InitForkChoice,
+		"bitwise":     bitwise.InitForkChoice,
+		"proto_array": proto_array.InitForkChoice,
+	}
+}
+
+// TestScenarios runs every scenario YAML under testdata/ against every
+// weighted implementation, failing if any of them disagrees with the
+// scenario's expected head at any step.
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.yaml")
+	if err != nil {
+		t.Fatalf("testharness: globbing testdata: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("testharness: no scenarios found under testdata/")
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			scenario, err := LoadScenario(path)
+			if err != nil {
+				t.Fatalf("loading scenario: %v", err)
+			}
+
+			for label, err := range RunAll(scenario, weightedImplementations()) {
+				if err != nil {
+					t.Errorf("%s: %v", label, err)
+				}
+			}
+		})
+	}
+}
+
+// TestFindHeadFromJustifiedRoot checks the behavior FindHead adds over
+// HeadFn: searching from an explicit justified root rather than always
+// from dag.Start. It builds two branches off genesis, puts every vote on
+// one of them (x), and confirms FindHead justified at the other (y) stays
+// inside y's subtree regardless - there's no path from y to x's votes, so
+// an implementation that ignored the justified root argument and searched
+// from dag.Start anyway would return a block under x instead.
+func TestFindHeadFromJustifiedRoot(t *testing.T) {
+	for label, init := range weightedImplementations() {
+		label, init := label, init
+		t.Run(label, func(t *testing.T) {
+			beaconDag := &dag.BeaconDag{Nodes: make(map[[32]byte]*dag.DagNode)}
+			gh := init(beaconDag)
+
+			genesis := NewTestNode("genesis", 0, nil)
+			beaconDag.Start = genesis
+			x := NewTestNode("x", 1, genesis)
+			xLeaf := NewTestNode("x-leaf", 2, x)
+			y := NewTestNode("y", 1, genesis)
+			yLeaf := NewTestNode("y-leaf", 2, y)
+
+			for _, node := range []*dag.DagNode{genesis, x, xLeaf, y, yLeaf} {
+				beaconDag.Nodes[node.Key] = node
+				if err := gh.AddBlock(node, defaultSpec); err != nil {
+					t.Fatalf("AddBlock(%x): %v", node.Key, err)
+				}
+			}
+			if err := gh.AddAttestation(0, xLeaf.Key, defaultSpec); err != nil {
+				t.Fatalf("AddAttestation: %v", err)
+			}
+
+			if head := gh.HeadFn(); head == nil || head.Key != xLeaf.Key {
+				t.Fatalf("HeadFn: expected x-leaf, got %x", headKey(head))
+			}
+
+			head, err := gh.FindHead(y.Key, defaultSpec)
+			if err != nil {
+				t.Fatalf("FindHead(y): %v", err)
+			}
+			if head == nil || head.Key != yLeaf.Key {
+				t.Fatalf("FindHead(y): expected y-leaf, got %x", headKey(head))
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package spec
+
+// / ChainSpec carries the handful of consensus constants passed to
+// / AddBlock, AddAttestation and FindHead, without pulling in the rest of
+// / the beacon-state config. No ForkChoice implementation in this repo reads
+// / its fields: every attestation counts as a vote of weight 1.
+type ChainSpec struct {
+	SlotsPerEpoch uint64
+	GenesisSlot   uint64
+
+	MaxEffectiveBalance       uint64
+	EffectiveBalanceIncrement uint64
+}